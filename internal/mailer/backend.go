@@ -0,0 +1,12 @@
+package mailer
+
+import "context"
+
+// Backend is implemented by concrete email delivery mechanisms. Mailer
+// delegates the actual transport to a Backend so callers can swap providers,
+// or use an in-memory backend in tests, without touching the template
+// rendering and fallback logic in Mailer.Send. A Backend is free to ignore
+// Message fields it has no provider-side support for (e.g. Tags on SMTP).
+type Backend interface {
+	Send(ctx context.Context, msg *Message) error
+}
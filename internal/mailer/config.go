@@ -0,0 +1,86 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Provider identifies which Backend implementation Mailer should build.
+type Provider string
+
+const (
+	ProviderMailgun Provider = "mailgun"
+	ProviderSMTP    Provider = "smtp"
+	ProviderSES     Provider = "ses"
+	ProviderNull    Provider = "null"
+	ProviderMemory  Provider = "memory"
+)
+
+// Config configures Mailer and the Backend(s) it builds. Any field left
+// zero-valued, other than Sender, is filled in from environment variables by
+// New, so existing
+// deployments that only set MAILGUN_API_KEY / MAILGUN_DOMAIN keep working
+// unchanged.
+type Config struct {
+	// Provider selects the primary backend. Defaults to ProviderMailgun.
+	Provider Provider
+	// Fallback, if set, selects a secondary backend that Mailer.Send
+	// retries against when the primary backend's Send fails.
+	Fallback Provider
+
+	Sender string
+
+	Mailgun MailgunConfig
+	SMTP    SMTPConfig
+	SES     SESConfig
+
+	// TemplateDevDir, if set, points TemplateRegistry at an on-disk copy of
+	// the templates directory to watch for hot-reload in development.
+	// Leave empty in production to only ever use the embedded templates.
+	TemplateDevDir string
+}
+
+// withEnvDefaults fills unset fields of cfg from environment variables.
+func withEnvDefaults(cfg Config) Config {
+	if cfg.Provider == "" {
+		cfg.Provider = Provider(os.Getenv("MAILER_PROVIDER"))
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderMailgun
+	}
+	if cfg.Fallback == "" {
+		cfg.Fallback = Provider(os.Getenv("MAILER_FALLBACK_PROVIDER"))
+	}
+	if cfg.TemplateDevDir == "" {
+		cfg.TemplateDevDir = os.Getenv("MAILER_TEMPLATE_DEV_DIR")
+	}
+
+	cfg.Mailgun = cfg.Mailgun.withEnvDefaults()
+	cfg.SMTP = cfg.SMTP.withEnvDefaults()
+	cfg.SES = cfg.SES.withEnvDefaults()
+
+	return cfg
+}
+
+func newBackend(p Provider, cfg Config) (Backend, error) {
+	switch p {
+	case ProviderMailgun:
+		return newMailgunBackend(cfg.Mailgun, cfg.Sender)
+	case ProviderSMTP:
+		return newSMTPBackend(cfg.SMTP, cfg.Sender)
+	case ProviderSES:
+		return newSESBackend(cfg.SES, cfg.Sender)
+	case ProviderNull:
+		return NewNullBackend(), nil
+	case ProviderMemory:
+		return NewMemoryBackend(), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer provider %q", p)
+	}
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
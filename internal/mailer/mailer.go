@@ -1,106 +1,116 @@
 package mailer
 
 import (
-	"bytes"
 	"context"
 	"embed"
 	"fmt"
-	"html/template"
-	"os"
+	"strings"
 	"time"
-
-	"github.com/mailgun/mailgun-go/v4"
 )
 
 //go:embed "templates"
 var templateFS embed.FS
 
+// Mailer renders templates and delivers the result through a Backend. When
+// cfg.Fallback is set, Send retries against a secondary Backend if the
+// primary one fails, so a provider outage doesn't take down transactional
+// email on its own.
 type Mailer struct {
-	client mailgun.Mailgun
-	sender string
-	domain string
+	backend   Backend
+	fallback  Backend
+	templates *TemplateRegistry
 }
 
-// New creates a new mailer instance using Mailgun
-// The host, port, username, password parameters are kept for compatibility but ignored
-// Mailgun uses API key and domain from environment variables
-func New(host string, port int, username, password, sender string) (*Mailer, error) {
-	apiKey := os.Getenv("MAILGUN_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("MAILGUN_API_KEY environment variable is required")
-	}
+// New builds a Mailer from cfg. Any zero-valued field in cfg other than
+// Sender (including cfg.Provider itself) falls back to the matching
+// environment variable, so
+// existing deployments that only set MAILGUN_API_KEY / MAILGUN_DOMAIN keep
+// working unchanged.
+func New(cfg Config) (*Mailer, error) {
+	cfg = withEnvDefaults(cfg)
 
-	domain := os.Getenv("MAILGUN_DOMAIN")
-	if domain == "" {
-		return nil, fmt.Errorf("MAILGUN_DOMAIN environment variable is required")
+	backend, err := newBackend(cfg.Provider, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to configure %s backend: %w", cfg.Provider, err)
 	}
 
-	// Create Mailgun client
-	mg := mailgun.NewMailgun(domain, apiKey)
+	templates, err := NewTemplateRegistry(templateFS, cfg.TemplateDevDir)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to load templates: %w", err)
+	}
 
-	// Set EU endpoint if needed (uncomment if using EU servers)
-	// mg.SetAPIBase(mailgun.APIBaseEU)
+	m := &Mailer{backend: backend, templates: templates}
 
-	mailer := &Mailer{
-		client: mg,
-		sender: sender,
-		domain: domain,
+	if cfg.Fallback != "" {
+		fallback, err := newBackend(cfg.Fallback, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: failed to configure %s fallback backend: %w", cfg.Fallback, err)
+		}
+		m.fallback = fallback
 	}
 
-	return mailer, nil
+	return m, nil
 }
 
-// Send sends an email using Mailgun API with template support
-func (m *Mailer) Send(recipient string, templateFile string, data any) error {
-	// Parse templates
-	tmpl, err := template.New("").ParseFS(templateFS, fmt.Sprintf("templates/%s", templateFile))
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
-	}
+// NewMailgunMailer builds a Mailer using the Mailgun backend, matching the
+// positional signature New had before it grew pluggable backends. host,
+// port, username, and password are accepted but ignored, exactly as before:
+// Mailgun is configured purely from MAILGUN_API_KEY/MAILGUN_DOMAIN (and any
+// of the MailgunConfig env vars New(Config{}) already honors). Kept so
+// out-of-tree callers built against the old New don't break; new code should
+// call New(Config{...}) directly.
+func NewMailgunMailer(host string, port int, username, password, sender string) (*Mailer, error) {
+	return New(Config{Provider: ProviderMailgun, Sender: sender})
+}
 
-	// Execute subject template
-	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
-	if err != nil {
-		return fmt.Errorf("failed to execute subject template: %w", err)
-	}
+// Send renders templateFile against data and delivers it to recipient. It is
+// a thin wrapper around SendMessage for the common single-recipient case;
+// use SendMessage directly for CC/BCC, attachments, tags, custom headers,
+// scheduled delivery, or per-recipient template variables. If data
+// implements LocaleProvider, its locale selects the "<templateFile>.<locale>"
+// template; otherwise DefaultLocale is used. templateFile is registered in
+// the TemplateRegistry by base name (no extension); a trailing ".tmpl", kept
+// for compatibility with callers predating the registry, is stripped before
+// lookup.
+func (m *Mailer) Send(recipient string, templateFile string, data any) error {
+	name := strings.TrimSuffix(templateFile, ".tmpl")
 
-	// Execute plain body template
-	plainBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
-	if err != nil {
-		return fmt.Errorf("failed to execute plain body template: %w", err)
+	locale := DefaultLocale
+	if lp, ok := data.(LocaleProvider); ok && lp.Locale() != "" {
+		locale = lp.Locale()
 	}
 
-	// Execute HTML body template
-	htmlBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
+	subject, plainBody, htmlBody, err := m.templates.Render(name, locale, data)
 	if err != nil {
-		return fmt.Errorf("failed to execute HTML body template: %w", err)
+		return err
 	}
 
-	// Create Mailgun message
-	message := m.client.NewMessage(
-		m.sender,
-		subject.String(),
-		plainBody.String(),
-		recipient,
-	)
-
-	// Set HTML version
-	message.SetHtml(htmlBody.String())
+	return m.SendMessage(&Message{
+		To:        []string{recipient},
+		Subject:   subject,
+		PlainBody: plainBody,
+		HTMLBody:  htmlBody,
+	})
+}
 
-	// Send the email with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+// SendMessage delivers msg through the configured Backend, retrying against
+// the fallback Backend (if any) when the primary delivery fails.
+func (m *Mailer) SendMessage(msg *Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	resp, id, err := m.client.Send(ctx, message)
-	if err != nil {
-		return fmt.Errorf("failed to send email via Mailgun: %w", err)
+	sendErr := m.backend.Send(ctx, msg)
+	if sendErr == nil {
+		return nil
+	}
+
+	if m.fallback == nil {
+		return sendErr
 	}
 
-	// Log success (optional - you can remove this)
-	fmt.Printf("Mailgun email sent successfully. Message ID: %s, Response: %s\n", id, resp)
+	if fallbackErr := m.fallback.Send(ctx, msg); fallbackErr != nil {
+		return fmt.Errorf("primary backend failed: %w; fallback backend also failed: %v", sendErr, fallbackErr)
+	}
 
 	return nil
 }
@@ -0,0 +1,131 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mailgun/mailgun-go/v4"
+)
+
+// Region selects which Mailgun API base a MailgunConfig should use.
+type Region string
+
+const (
+	RegionUS Region = "us"
+	RegionEU Region = "eu"
+)
+
+// MailgunConfig configures the Mailgun backend. Region picks between
+// Mailgun's US (default) and EU API bases; APIBase overrides both with an
+// exact URL, for private/self-hosted Mailgun-compatible endpoints.
+type MailgunConfig struct {
+	APIKey  string
+	Domain  string
+	Region  Region
+	APIBase string
+}
+
+func (c MailgunConfig) withEnvDefaults() MailgunConfig {
+	if c.APIKey == "" {
+		c.APIKey = os.Getenv("MAILGUN_API_KEY")
+	}
+	if c.Domain == "" {
+		c.Domain = os.Getenv("MAILGUN_DOMAIN")
+	}
+	if c.Region == "" {
+		c.Region = Region(os.Getenv("MAILGUN_REGION"))
+	}
+	if c.APIBase == "" {
+		c.APIBase = os.Getenv("MAILGUN_API_BASE")
+	}
+	return c
+}
+
+// apiBase resolves the Mailgun API base to use, giving an explicit APIBase
+// precedence over Region. An empty result means "use the client's default
+// (US) base".
+func (c MailgunConfig) apiBase() string {
+	if c.APIBase != "" {
+		return c.APIBase
+	}
+	if c.Region == RegionEU {
+		return mailgun.APIBaseEU
+	}
+	return ""
+}
+
+type mailgunBackend struct {
+	client mailgun.Mailgun
+	sender string
+}
+
+func newMailgunBackend(cfg MailgunConfig, sender string) (Backend, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("MAILGUN_API_KEY environment variable is required")
+	}
+	if cfg.Domain == "" {
+		return nil, fmt.Errorf("MAILGUN_DOMAIN environment variable is required")
+	}
+
+	mg := mailgun.NewMailgun(cfg.Domain, cfg.APIKey)
+	if apiBase := cfg.apiBase(); apiBase != "" {
+		mg.SetAPIBase(apiBase)
+	}
+
+	return &mailgunBackend{client: mg, sender: sender}, nil
+}
+
+func (b *mailgunBackend) Send(ctx context.Context, msg *Message) error {
+	var message *mailgun.Message
+	if len(msg.RecipientVariables) > 0 {
+		// A personalized batch send: recipients come from the variables
+		// map so each one can be paired with its own variables.
+		message = b.client.NewMessage(b.sender, msg.Subject, msg.PlainBody)
+		for recipient, vars := range msg.RecipientVariables {
+			if err := message.AddRecipientAndVariables(recipient, vars); err != nil {
+				return fmt.Errorf("failed to add recipient %s: %w", recipient, err)
+			}
+		}
+	} else {
+		message = b.client.NewMessage(b.sender, msg.Subject, msg.PlainBody, msg.To...)
+	}
+	message.SetHtml(msg.HTMLBody)
+
+	for _, cc := range msg.CC {
+		message.AddCC(cc)
+	}
+	for _, bcc := range msg.BCC {
+		message.AddBCC(bcc)
+	}
+	if len(msg.Tags) > 0 {
+		if err := message.AddTag(msg.Tags...); err != nil {
+			return fmt.Errorf("failed to add Mailgun tags: %w", err)
+		}
+	}
+	for header, value := range msg.Headers {
+		message.AddHeader(header, value)
+	}
+	for _, a := range msg.Attachments {
+		if a.Inline {
+			message.AddReaderInline(a.Filename, io.NopCloser(bytes.NewReader(a.Data)))
+		} else {
+			message.AddBufferAttachment(a.Filename, a.Data)
+		}
+	}
+	if !msg.DeliveryTime.IsZero() {
+		message.SetDeliveryTime(msg.DeliveryTime)
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, _, err := b.client.Send(sendCtx, message); err != nil {
+		return fmt.Errorf("failed to send email via Mailgun: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBackend is a Backend that records every message in memory instead
+// of delivering it, so tests can assert on what would have been sent
+// without talking to a real provider.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	Messages []Message
+}
+
+// NewMemoryBackend returns an empty MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Send(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.Messages = append(b.Messages, *msg)
+
+	return nil
+}
+
+// NullBackend is a Backend that discards every message and never errors,
+// useful for local development or tests that don't care about email at all.
+type NullBackend struct{}
+
+// NewNullBackend returns a NullBackend.
+func NewNullBackend() *NullBackend {
+	return &NullBackend{}
+}
+
+func (*NullBackend) Send(ctx context.Context, msg *Message) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"sort"
+	"time"
+)
+
+// Attachment is a file carried along with a Message, either as a regular
+// attachment or, when Inline is true, referenced from the HTML body (e.g.
+// via a "cid:" URL) such as an embedded logo image.
+type Attachment struct {
+	Filename string
+	Data     []byte
+	Inline   bool
+}
+
+// Message is the full set of options Backend.Send can act on. Mailer.Send
+// builds one from a rendered template for the common single-recipient case;
+// Mailer.SendMessage accepts one directly for everything else (CC/BCC,
+// attachments, tags, custom headers, scheduled delivery, and per-recipient
+// template variables for a personalized batch send).
+type Message struct {
+	To  []string
+	CC  []string
+	BCC []string
+
+	Subject   string
+	PlainBody string
+	HTMLBody  string
+
+	Attachments []Attachment
+
+	// Tags are attached to the message for provider-side analytics and
+	// event tracking. Backends that don't support tagging ignore these.
+	Tags []string
+
+	// Headers are added as custom MIME headers on the outgoing message.
+	Headers map[string]string
+
+	// DeliveryTime, if set, schedules the message for later delivery.
+	// Backends that don't support scheduling ignore this and send
+	// immediately.
+	DeliveryTime time.Time
+
+	// RecipientVariables maps a recipient address to the template
+	// variables used to personalize its copy of the message. On backends
+	// that support per-recipient variables it takes precedence over To for
+	// determining recipients: a single Send call fans out one personalized
+	// email per key. Backends that don't support them use RecipientBatches
+	// instead, which prefers To when it is set; only when To is empty do
+	// they send an identical copy to each key's address, one message per
+	// recipient rather than one shared message naming every recipient.
+	RecipientVariables map[string]map[string]any
+}
+
+// RecipientBatches returns the envelope "To" groups Backend.Send should
+// submit as separate messages: a single group containing To when set, or
+// else one single-address group per RecipientVariables key in sorted order.
+// This is how a Message built purely from a personalized batch (To empty,
+// RecipientVariables set) still has somewhere to go on backends that can't
+// personalize a single send, without collapsing every recipient into one
+// shared To header that discloses the whole list to each of them.
+func (m *Message) RecipientBatches() [][]string {
+	if len(m.To) > 0 {
+		return [][]string{m.To}
+	}
+	if len(m.RecipientVariables) == 0 {
+		return nil
+	}
+	recipients := make([]string, 0, len(m.RecipientVariables))
+	for recipient := range m.RecipientVariables {
+		recipients = append(recipients, recipient)
+	}
+	sort.Strings(recipients)
+
+	batches := make([][]string, len(recipients))
+	for i, recipient := range recipients {
+		batches[i] = []string{recipient}
+	}
+	return batches
+}
@@ -0,0 +1,370 @@
+package mailer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mailgun/mailgun-go/v4"
+)
+
+// QueueOptions configures a Queue.
+type QueueOptions struct {
+	// DB is the Postgres connection pool backing the pending and dead
+	// letter tables. Required.
+	DB *sql.DB
+
+	// Workers is the number of goroutines processing the queue concurrently.
+	Workers int
+	// MaxAttempts is how many send attempts (the first send included) a
+	// message gets before it is moved to the dead letter table.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the exponential backoff applied between
+	// retries.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// PollInterval is how often an idle worker checks for due messages.
+	PollInterval time.Duration
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = time.Second
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 5 * time.Minute
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	return o
+}
+
+// QueueStats is a snapshot of a Queue's lifetime counters.
+type QueueStats struct {
+	Enqueued int64
+	Sent     int64
+	Retried  int64
+	Failed   int64
+}
+
+// Queue is a background worker pool that persists pending sends to Postgres
+// and delivers them through a Mailer, decoupling callers from a provider's
+// synchronous send latency and outages. Transient failures are retried with
+// exponential backoff and jitter up to QueueOptions.MaxAttempts; messages
+// that exhaust their attempts (or fail for a non-transient reason) are moved
+// to the failed_emails dead letter table.
+type Queue struct {
+	mailer *Mailer
+	db     *sql.DB
+	opts   QueueOptions
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	stats QueueStats
+}
+
+// NewQueue creates the pending_emails and failed_emails tables if they don't
+// already exist and starts opts.Workers goroutines processing the queue
+// through m.
+func NewQueue(m *Mailer, opts QueueOptions) (*Queue, error) {
+	opts = opts.withDefaults()
+	if opts.DB == nil {
+		return nil, fmt.Errorf("mailer: QueueOptions.DB is required")
+	}
+
+	if err := ensureQueueSchema(opts.DB); err != nil {
+		return nil, fmt.Errorf("mailer: failed to prepare queue schema: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{mailer: m, db: opts.DB, opts: opts, cancel: cancel}
+
+	for i := 0; i < opts.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker(ctx)
+	}
+
+	return q, nil
+}
+
+func ensureQueueSchema(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS pending_emails (
+			id uuid PRIMARY KEY,
+			message jsonb NOT NULL,
+			attempts int NOT NULL DEFAULT 0,
+			next_attempt_at timestamptz NOT NULL DEFAULT now(),
+			created_at timestamptz NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS failed_emails (
+			id uuid PRIMARY KEY,
+			message jsonb NOT NULL,
+			attempts int NOT NULL,
+			last_error text,
+			failed_at timestamptz NOT NULL DEFAULT now()
+		)
+	`)
+	return err
+}
+
+// Enqueue persists msg for background delivery and returns once it has been
+// durably recorded; actual delivery happens asynchronously on the worker
+// pool.
+func (q *Queue) Enqueue(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("mailer: failed to marshal queued message: %w", err)
+	}
+
+	_, err = q.db.ExecContext(ctx,
+		`INSERT INTO pending_emails (id, message, attempts, next_attempt_at) VALUES ($1, $2, 0, now())`,
+		uuid.New(), payload,
+	)
+	if err != nil {
+		return fmt.Errorf("mailer: failed to enqueue message: %w", err)
+	}
+
+	q.mu.Lock()
+	q.stats.Enqueued++
+	q.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns a snapshot of the queue's lifetime counters.
+func (q *Queue) Stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// Replay moves a message out of the dead letter table and back onto the
+// pending queue for another attempt, resetting its attempt count.
+func (q *Queue) Replay(ctx context.Context, id uuid.UUID) error {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("mailer: failed to begin replay transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var payload []byte
+	if err := tx.QueryRowContext(ctx, `SELECT message FROM failed_emails WHERE id = $1`, id).Scan(&payload); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("mailer: no failed message with id %s", id)
+		}
+		return fmt.Errorf("mailer: failed to load failed message: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO pending_emails (id, message, attempts, next_attempt_at) VALUES ($1, $2, 0, now())`,
+		id, payload,
+	); err != nil {
+		return fmt.Errorf("mailer: failed to requeue message: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM failed_emails WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("mailer: failed to remove replayed message from dead letter queue: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Close stops accepting new work on the worker pool and waits for
+// in-flight sends to finish.
+func (q *Queue) Close() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+func (q *Queue) worker(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for q.processOne(ctx) {
+			}
+		}
+	}
+}
+
+// claimLease bounds how long a claimed message is held invisible while it's
+// being sent. It's set well above the 30s send timeout in
+// Mailer.SendMessage so a healthy send always finalizes before the lease
+// expires; a worker that crashes mid-send simply lets the message become
+// due again after the lease, rather than losing it.
+const claimLease = 2 * time.Minute
+
+// processOne claims and processes at most one due message, returning true
+// if it found one (so the worker can keep draining the queue without
+// waiting for the next poll tick). Claiming a row and recording the send
+// outcome are separate, short transactions; the row's FOR UPDATE lock is
+// released (via claim's commit) before the network send happens, so a
+// worker blocked on a slow or down provider holds no DB connection or lock
+// for the duration of the send.
+func (q *Queue) processOne(ctx context.Context) bool {
+	id, payload, attempts, ok := q.claim(ctx)
+	if !ok {
+		return false
+	}
+
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		q.deadLetter(ctx, id, payload, attempts, err)
+		return true
+	}
+
+	if sendErr := q.mailer.SendMessage(&msg); sendErr != nil {
+		attempts++
+		if attempts >= q.opts.MaxAttempts || !isTransientSendError(sendErr) {
+			q.deadLetter(ctx, id, payload, attempts, sendErr)
+			return true
+		}
+
+		delay := backoffWithJitter(attempts, q.opts.BaseDelay, q.opts.MaxDelay)
+		if _, err := q.db.ExecContext(ctx,
+			`UPDATE pending_emails SET attempts = $1, next_attempt_at = now() + $2 WHERE id = $3`,
+			attempts, delay, id,
+		); err != nil {
+			return true
+		}
+
+		q.mu.Lock()
+		q.stats.Retried++
+		q.mu.Unlock()
+		return true
+	}
+
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM pending_emails WHERE id = $1`, id); err != nil {
+		return true
+	}
+
+	q.mu.Lock()
+	q.stats.Sent++
+	q.mu.Unlock()
+	return true
+}
+
+// claim locks and returns the next due message, bumping its next_attempt_at
+// past claimLease so no other worker picks it up while it's in flight, then
+// commits immediately to release the row lock. ok is false if there was no
+// due message or the claim failed.
+func (q *Queue) claim(ctx context.Context) (id uuid.UUID, payload []byte, attempts int, ok bool) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.UUID{}, nil, 0, false
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, message, attempts FROM pending_emails
+		WHERE next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		FOR UPDATE SKIP LOCKED
+		LIMIT 1
+	`).Scan(&id, &payload, &attempts)
+	if err != nil {
+		return uuid.UUID{}, nil, 0, false
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE pending_emails SET next_attempt_at = now() + $1 WHERE id = $2`,
+		claimLease, id,
+	); err != nil {
+		return uuid.UUID{}, nil, 0, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.UUID{}, nil, 0, false
+	}
+
+	return id, payload, attempts, true
+}
+
+// deadLetter moves a message from pending_emails to failed_emails in its
+// own short transaction. Failures here leave the row claimed in
+// pending_emails, where it naturally becomes visible again once claimLease
+// expires, so a broken dead letter insert never silently drops a message.
+func (q *Queue) deadLetter(ctx context.Context, id uuid.UUID, payload []byte, attempts int, cause error) {
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO failed_emails (id, message, attempts, last_error) VALUES ($1, $2, $3, $4)`,
+		id, payload, attempts, cause.Error(),
+	); err != nil {
+		return
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pending_emails WHERE id = $1`, id); err != nil {
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		return
+	}
+
+	q.mu.Lock()
+	q.stats.Failed++
+	q.mu.Unlock()
+}
+
+// isTransientSendError reports whether err looks like a temporary failure
+// worth retrying (5xx responses, network timeouts) as opposed to a
+// permanent one (bad request, invalid recipient) that retrying won't fix.
+func isTransientSendError(err error) bool {
+	var unexpected *mailgun.UnexpectedResponseError
+	if errors.As(err, &unexpected) {
+		return unexpected.Actual >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout()
+	}
+
+	// Unrecognized error shapes (SMTP, SES, or a wrapped fallback error)
+	// are treated as transient so the queue doesn't give up too eagerly.
+	return true
+}
+
+// backoffWithJitter returns the delay before retrying attempt, doubling
+// base per attempt (capped at max) and adding up to one base unit of
+// jitter to avoid retry storms across many queued messages.
+func backoffWithJitter(attempt int, base, max time.Duration) time.Duration {
+	delay := base * time.Duration(1<<uint(attempt-1))
+	if delay <= 0 || delay > max {
+		delay = max
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(base) + 1))
+
+	return delay + jitter
+}
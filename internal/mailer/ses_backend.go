@@ -0,0 +1,82 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ses"
+	"github.com/aws/aws-sdk-go-v2/service/ses/types"
+)
+
+// SESConfig configures the Amazon SES backend.
+type SESConfig struct {
+	Region string
+}
+
+func (c SESConfig) withEnvDefaults() SESConfig {
+	if c.Region == "" {
+		c.Region = os.Getenv("SES_REGION")
+	}
+	return c
+}
+
+type sesBackend struct {
+	client *ses.Client
+	sender string
+}
+
+func newSESBackend(cfg SESConfig, sender string) (Backend, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("SES_REGION environment variable is required")
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config for SES: %w", err)
+	}
+
+	return &sesBackend{client: ses.NewFromConfig(awsCfg), sender: sender}, nil
+}
+
+// Send delivers msg via SES's SendRawEmail API, reusing the same MIME
+// builder as the SMTP backend so CC/BCC, attachments, and custom headers
+// are honored. Tags and scheduled delivery have no SES equivalent and are
+// ignored. Per-recipient template variables aren't used to personalize the
+// body (see Message.RecipientVariables), but their keys still populate the
+// recipient list via Message.RecipientBatches when To is empty — one
+// SendRawEmail call per recipient, same as the SMTP backend, rather than
+// one shared message naming everyone.
+func (b *sesBackend) Send(ctx context.Context, msg *Message) error {
+	batches := msg.RecipientBatches()
+	if len(batches) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+
+	for _, to := range batches {
+		raw, err := buildMIMEMessage(b.sender, msg, to)
+		if err != nil {
+			return fmt.Errorf("failed to build SES message: %w", err)
+		}
+
+		// SES resolves recipients purely from the To:/Cc: headers present in
+		// the raw message unless Destinations is set explicitly;
+		// buildMIMEMessage deliberately never writes a Bcc: header, so BCC
+		// recipients must be passed here instead, mirroring how
+		// smtp_backend.go passes BCC through SendMail's envelope recipients
+		// rather than a header.
+		destinations := append(append(append([]string{}, to...), msg.CC...), msg.BCC...)
+
+		input := &ses.SendRawEmailInput{
+			RawMessage:   &types.RawMessage{Data: raw},
+			Destinations: destinations,
+		}
+
+		if _, err := b.client.SendRawEmail(ctx, input); err != nil {
+			return fmt.Errorf("failed to send email via SES: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,302 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// base64LineLength is the maximum encoded line length RFC 2045 recommends
+// for base64 body parts, well under the 998-octet hard limit RFC 5321
+// §4.5.3.1.6 places on SMTP lines.
+const base64LineLength = 76
+
+// base64InputChunkSize is how many input bytes encode to exactly one
+// base64LineLength line (each 3 input bytes become 4 output characters).
+const base64InputChunkSize = base64LineLength / 4 * 3
+
+// SMTPConfig configures the plain SMTP backend.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+func (c SMTPConfig) withEnvDefaults() SMTPConfig {
+	if c.Host == "" {
+		c.Host = os.Getenv("SMTP_HOST")
+	}
+	if c.Port == 0 {
+		c.Port = atoiOrZero(os.Getenv("SMTP_PORT"))
+	}
+	if c.Username == "" {
+		c.Username = os.Getenv("SMTP_USERNAME")
+	}
+	if c.Password == "" {
+		c.Password = os.Getenv("SMTP_PASSWORD")
+	}
+	return c
+}
+
+type smtpBackend struct {
+	cfg    SMTPConfig
+	sender string
+}
+
+func newSMTPBackend(cfg SMTPConfig, sender string) (Backend, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP_HOST environment variable is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+
+	return &smtpBackend{cfg: cfg, sender: sender}, nil
+}
+
+// Send delivers msg over SMTP. net/smtp has no context support, so ctx is
+// only honored up to the point the dial/auth/send sequence starts. Tags and
+// custom delivery scheduling have no SMTP equivalent and are ignored.
+// Per-recipient template variables aren't used to personalize the body (see
+// Message.RecipientVariables), but when To is empty their keys still drive
+// delivery via Message.RecipientBatches: one message per recipient.
+func (b *smtpBackend) Send(ctx context.Context, msg *Message) error {
+	batches := msg.RecipientBatches()
+	if len(batches) == 0 {
+		return fmt.Errorf("message has no recipients")
+	}
+
+	addr := fmt.Sprintf("%s:%d", b.cfg.Host, b.cfg.Port)
+
+	var auth smtp.Auth
+	if b.cfg.Username != "" {
+		auth = smtp.PlainAuth("", b.cfg.Username, b.cfg.Password, b.cfg.Host)
+	}
+
+	for _, to := range batches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		raw, err := buildMIMEMessage(b.sender, msg, to)
+		if err != nil {
+			return fmt.Errorf("failed to build SMTP message: %w", err)
+		}
+
+		recipients := make([]string, 0, len(to)+len(msg.CC)+len(msg.BCC))
+		recipients = append(recipients, to...)
+		recipients = append(recipients, msg.CC...)
+		recipients = append(recipients, msg.BCC...)
+
+		if err := smtp.SendMail(addr, auth, b.sender, recipients, raw); err != nil {
+			return fmt.Errorf("failed to send email via SMTP: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// buildMIMEMessage renders msg as an RFC 5322 message suitable for
+// net/smtp.SendMail: a multipart/alternative plain+HTML body, wrapped in a
+// multipart/mixed envelope when there are attachments. to is the envelope
+// "To" group for this copy (one element of msg.RecipientBatches()), kept
+// separate from msg so a personalized batch send can be split into one
+// message per recipient instead of one shared To header naming everyone.
+func buildMIMEMessage(from string, msg *Message, to []string) ([]byte, error) {
+	if err := validateHeaderValue(from); err != nil {
+		return nil, fmt.Errorf("invalid From address: %w", err)
+	}
+	for _, addr := range to {
+		if err := validateHeaderValue(addr); err != nil {
+			return nil, fmt.Errorf("invalid To address: %w", err)
+		}
+	}
+	for _, addr := range msg.CC {
+		if err := validateHeaderValue(addr); err != nil {
+			return nil, fmt.Errorf("invalid Cc address: %w", err)
+		}
+	}
+	for header, value := range msg.Headers {
+		if err := validateHeaderValue(header); err != nil {
+			return nil, fmt.Errorf("invalid header name %q: %w", header, err)
+		}
+		if err := validateHeaderValue(value); err != nil {
+			return nil, fmt.Errorf("invalid value for header %q: %w", header, err)
+		}
+	}
+
+	altBuf, altBoundary, err := buildAlternativePart(msg.PlainBody, msg.HTMLBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	if len(msg.CC) > 0 {
+		fmt.Fprintf(&buf, "Cc: %s\r\n", strings.Join(msg.CC, ", "))
+	}
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	for header, value := range msg.Headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header, value)
+	}
+
+	if len(msg.Attachments) == 0 {
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", altBoundary)
+		buf.Write(altBuf)
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	altPart, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + altBoundary},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := altPart.Write(altBuf); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		if err := writeAttachmentPart(mixed, a); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// validateHeaderValue rejects strings containing any CR or LF, which
+// would otherwise let a caller inject extra headers or terminate the header
+// block early when a header name, address, or value built from
+// user-influenced content (a templated Reply-To, say) is written straight
+// into the raw RFC 5322 message.
+func validateHeaderValue(s string) error {
+	if strings.ContainsAny(s, "\r\n") {
+		return fmt.Errorf("must not contain CR or LF")
+	}
+	return nil
+}
+
+// buildAlternativePart renders plainBody and htmlBody as a standalone
+// multipart/alternative body, returning its bytes and boundary so the
+// caller can embed it directly or nest it inside a multipart/mixed part.
+// Both parts are sent quoted-printable, whose soft line breaks keep a long
+// (e.g. minified single-line) body under the SMTP line-length limit without
+// altering its content.
+func buildAlternativePart(plainBody, htmlBody string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	plainPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := writeQuotedPrintable(plainPart, plainBody); err != nil {
+		return nil, "", err
+	}
+
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := writeQuotedPrintable(htmlPart, htmlBody); err != nil {
+		return nil, "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.Boundary(), nil
+}
+
+// writeQuotedPrintable writes body to w quoted-printable encoded.
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func writeAttachmentPart(mixed *multipart.Writer, a Attachment) error {
+	if err := validateHeaderValue(a.Filename); err != nil {
+		return fmt.Errorf("invalid attachment filename: %w", err)
+	}
+
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(a.Filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	filename := strings.ReplaceAll(a.Filename, `"`, `\"`)
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf(`%s; filename="%s"`, disposition, filename)},
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeBase64Wrapped(part, a.Data)
+}
+
+// writeBase64Wrapped base64-encodes data and writes it to w wrapped at
+// base64LineLength, CRLF-separated, per RFC 2045 — an unbroken encoded line
+// would otherwise exceed the SMTP line-length limit for any non-trivial
+// attachment.
+func writeBase64Wrapped(w io.Writer, data []byte) error {
+	line := make([]byte, base64LineLength)
+	for len(data) > 0 {
+		n := len(data)
+		if n > base64InputChunkSize {
+			n = base64InputChunkSize
+		}
+
+		encodedLen := base64.StdEncoding.EncodedLen(n)
+		base64.StdEncoding.Encode(line[:encodedLen], data[:n])
+
+		if _, err := w.Write(line[:encodedLen]); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("\r\n")); err != nil {
+			return err
+		}
+
+		data = data[n:]
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBuildMIMEMessageRejectsCRLFInjection(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		msg  *Message
+	}{
+		{
+			name: "CRLF in To address",
+			from: "sender@example.com",
+			msg:  &Message{To: []string{"victim@example.com\r\nBcc: attacker@example.com"}},
+		},
+		{
+			name: "CRLF in Cc address",
+			from: "sender@example.com",
+			msg:  &Message{To: []string{"victim@example.com"}, CC: []string{"cc@example.com\r\nX-Injected: 1"}},
+		},
+		{
+			name: "CRLF in From",
+			from: "sender@example.com\r\nBcc: attacker@example.com",
+			msg:  &Message{To: []string{"victim@example.com"}},
+		},
+		{
+			name: "CRLF in custom header",
+			from: "sender@example.com",
+			msg: &Message{
+				To:      []string{"victim@example.com"},
+				Headers: map[string]string{"Reply-To": "reply@example.com\r\nBcc: attacker@example.com"},
+			},
+		},
+		{
+			name: "CRLF in attachment filename",
+			from: "sender@example.com",
+			msg: &Message{
+				To: []string{"victim@example.com"},
+				Attachments: []Attachment{
+					{Filename: "report.txt\r\nX-Injected: 1", Data: []byte("data")},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildMIMEMessage(tt.from, tt.msg, tt.msg.To); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestBuildMIMEMessageWellFormed(t *testing.T) {
+	msg := &Message{
+		To:        []string{"to@example.com"},
+		CC:        []string{"cc@example.com"},
+		Subject:   "hello",
+		PlainBody: "plain body",
+		HTMLBody:  "<p>html body</p>",
+	}
+
+	raw, err := buildMIMEMessage("sender@example.com", msg, msg.To)
+	if err != nil {
+		t.Fatalf("buildMIMEMessage: %v", err)
+	}
+
+	out := string(raw)
+	for _, want := range []string{"From: sender@example.com", "To: to@example.com", "Cc: cc@example.com", "Content-Type: multipart/alternative"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "multipart/mixed") {
+		t.Errorf("expected no multipart/mixed envelope without attachments, got:\n%s", out)
+	}
+}
+
+func TestBuildMIMEMessageWithAttachments(t *testing.T) {
+	msg := &Message{
+		To:        []string{"to@example.com"},
+		Subject:   "with attachment",
+		PlainBody: "plain body",
+		HTMLBody:  "<p>html body</p>",
+		Attachments: []Attachment{
+			{Filename: "report.txt", Data: []byte("report contents")},
+		},
+	}
+
+	raw, err := buildMIMEMessage("sender@example.com", msg, msg.To)
+	if err != nil {
+		t.Fatalf("buildMIMEMessage: %v", err)
+	}
+
+	out := string(raw)
+	for _, want := range []string{"Content-Type: multipart/mixed", `filename="report.txt"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected message to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecipientBatchesSplitsRecipientVariablesIntoOnePerMessage(t *testing.T) {
+	msg := &Message{
+		Subject:   "batch",
+		PlainBody: "plain body",
+		HTMLBody:  "<p>html body</p>",
+		RecipientVariables: map[string]map[string]any{
+			"a@example.com": {"name": "A"},
+			"b@example.com": {"name": "B"},
+		},
+	}
+
+	batches := msg.RecipientBatches()
+	if len(batches) != 2 {
+		t.Fatalf("expected one batch per recipient, got %d: %v", len(batches), batches)
+	}
+
+	for _, to := range batches {
+		if len(to) != 1 {
+			t.Fatalf("expected each batch to name exactly one recipient, got %v", to)
+		}
+
+		raw, err := buildMIMEMessage("sender@example.com", msg, to)
+		if err != nil {
+			t.Fatalf("buildMIMEMessage: %v", err)
+		}
+
+		out := string(raw)
+		if !strings.Contains(out, "To: "+to[0]) {
+			t.Errorf("expected To header naming only %s, got:\n%s", to[0], out)
+		}
+		for _, other := range batches {
+			if other[0] != to[0] && strings.Contains(out, other[0]) {
+				t.Errorf("expected message for %s not to disclose other recipient %s, got:\n%s", to[0], other[0], out)
+			}
+		}
+	}
+}
+
+func TestWriteBase64WrappedLimitsLineLength(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 300)
+
+	var buf bytes.Buffer
+	if err := writeBase64Wrapped(&buf, data); err != nil {
+		t.Fatalf("writeBase64Wrapped: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > base64LineLength {
+			t.Errorf("line exceeds %d chars: %q", base64LineLength, line)
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(buf.String(), "\r\n", ""))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !bytes.Equal(decoded, data) {
+		t.Error("decoded output does not round-trip to the original data")
+	}
+}
+
+func TestBuildAlternativePartEncodesQuotedPrintable(t *testing.T) {
+	longLine := strings.Repeat("a", 200)
+
+	raw, _, err := buildAlternativePart(longLine, "<p>"+longLine+"</p>")
+	if err != nil {
+		t.Fatalf("buildAlternativePart: %v", err)
+	}
+
+	out := string(raw)
+	if !strings.Contains(out, "Content-Transfer-Encoding: quoted-printable") {
+		t.Errorf("expected quoted-printable parts, got:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("line exceeds quoted-printable's 76-char limit: %q", line)
+		}
+	}
+}
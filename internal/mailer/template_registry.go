@@ -0,0 +1,204 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultLocale is used when send data doesn't implement LocaleProvider, or
+// names a locale with no matching template.
+const DefaultLocale = "en"
+
+// LocaleProvider is implemented by send data that carries an explicit
+// locale, letting Mailer.Send pick the matching "<name>.<locale>.tmpl"
+// template. Data that doesn't implement it gets DefaultLocale.
+type LocaleProvider interface {
+	Locale() string
+}
+
+// TemplateRegistry parses every embedded template once at startup and
+// caches the compiled *template.Template per name, removing the per-send
+// parse overhead of parsing from the embed.FS on every call under
+// queue-driven load. In dev mode it can additionally watch an on-disk
+// templates directory and hot-reload a template as soon as its file
+// changes, without a rebuild.
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]*template.Template
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewTemplateRegistry parses every "*.tmpl" file under "templates" in fsys,
+// keyed by filename without the ".tmpl" suffix (e.g.
+// "user_verification.en.tmpl" registers as "user_verification.en"). When
+// devDir is non-empty, it also watches devDir on disk and reparses a
+// template from there whenever its file changes; pass "" to disable
+// watching in production, where only the embedded copy is ever used.
+func NewTemplateRegistry(fsys embed.FS, devDir string) (*TemplateRegistry, error) {
+	r := &TemplateRegistry{templates: make(map[string]*template.Template)}
+
+	entries, err := fsys.ReadDir("templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+
+		content, err := fsys.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %q: %w", entry.Name(), err)
+		}
+
+		if err := r.register(entry.Name(), content); err != nil {
+			return nil, err
+		}
+	}
+
+	if devDir != "" {
+		if err := r.watch(devDir); err != nil {
+			return nil, fmt.Errorf("failed to watch templates directory: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *TemplateRegistry) register(filename string, content []byte) error {
+	tmpl, err := template.New(filename).Parse(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse template %q: %w", filename, err)
+	}
+
+	key := strings.TrimSuffix(filename, ".tmpl")
+
+	r.mu.Lock()
+	r.templates[key] = tmpl
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *TemplateRegistry) lookup(name, locale string) (*template.Template, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if locale != "" {
+		if t, ok := r.templates[name+"."+locale]; ok {
+			return t, true
+		}
+	}
+
+	t, ok := r.templates[name+"."+DefaultLocale]
+	return t, ok
+}
+
+// Render executes the "subject", "plainBody", and "htmlBody" blocks of the
+// template registered as name for locale (falling back to DefaultLocale)
+// against data.
+func (r *TemplateRegistry) Render(name, locale string, data any) (subject, plainBody, htmlBody string, err error) {
+	tmpl, ok := r.lookup(name, locale)
+	if !ok {
+		return "", "", "", fmt.Errorf("no template registered for %q (locale %q or default %q)", name, locale, DefaultLocale)
+	}
+
+	subjectBuf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subjectBuf, "subject", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to execute subject template: %w", err)
+	}
+
+	plainBodyBuf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(plainBodyBuf, "plainBody", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to execute plain body template: %w", err)
+	}
+
+	htmlBodyBuf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(htmlBodyBuf, "htmlBody", data); err != nil {
+		return "", "", "", fmt.Errorf("failed to execute HTML body template: %w", err)
+	}
+
+	return subjectBuf.String(), plainBodyBuf.String(), htmlBodyBuf.String(), nil
+}
+
+func (r *TemplateRegistry) watch(devDir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := watcher.Add(devDir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	r.watcher = watcher
+	r.done = make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				r.reloadFromDisk(event.Name)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("mailer: template watcher error: %v", err)
+			case <-r.done:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (r *TemplateRegistry) reloadFromDisk(path string) {
+	filename := filepath.Base(path)
+	if !strings.HasSuffix(filename, ".tmpl") {
+		return
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("mailer: failed to reload template %s: %v", path, err)
+		return
+	}
+
+	if err := r.register(filename, content); err != nil {
+		log.Printf("mailer: failed to reload template %s: %v", path, err)
+		return
+	}
+
+	log.Printf("mailer: hot-reloaded template %s", filename)
+}
+
+// Close stops watching the dev templates directory, if watching was
+// enabled. It is a no-op otherwise.
+func (r *TemplateRegistry) Close() error {
+	if r.watcher == nil {
+		return nil
+	}
+
+	close(r.done)
+	return r.watcher.Close()
+}
@@ -0,0 +1,51 @@
+// Package webhook receives and processes Mailgun delivery events (delivered,
+// opened, clicked, bounced, complained, unsubscribed), dispatching each to
+// user-registered callbacks and suppressing addresses that hard-bounce or
+// complain so the app stops sending to them.
+package webhook
+
+import "time"
+
+// DeliveredEvent reports that Mailgun successfully delivered a message.
+type DeliveredEvent struct {
+	Recipient string
+	MessageID string
+	Timestamp time.Time
+}
+
+// OpenedEvent reports that a recipient opened a message.
+type OpenedEvent struct {
+	Recipient  string
+	IP         string
+	ClientName string
+	Timestamp  time.Time
+}
+
+// ClickedEvent reports that a recipient clicked a link in a message.
+type ClickedEvent struct {
+	Recipient string
+	URL       string
+	Timestamp time.Time
+}
+
+// BounceEvent reports that a message could not be delivered. Severity is
+// "permanent" for a hard bounce or "temporary" for a soft one; only
+// permanent bounces suppress the recipient.
+type BounceEvent struct {
+	Recipient string
+	Severity  string
+	Reason    string
+	Timestamp time.Time
+}
+
+// ComplainedEvent reports that a recipient marked a message as spam.
+type ComplainedEvent struct {
+	Recipient string
+	Timestamp time.Time
+}
+
+// UnsubscribedEvent reports that a recipient unsubscribed from mailings.
+type UnsubscribedEvent struct {
+	Recipient string
+	Timestamp time.Time
+}
@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/mailgun/mailgun-go/v4"
+	"github.com/mailgun/mailgun-go/v4/events"
+)
+
+// Handler is an http.Handler that receives Mailgun webhook events, verifies
+// their signature, and dispatches them to registered callbacks. If db is
+// set, it also deduplicates deliveries by signature token and suppresses
+// recipients that hard-bounce or complain.
+type Handler struct {
+	signingKey string
+	db         *sql.DB
+
+	mu             sync.Mutex
+	onDelivered    []func(DeliveredEvent)
+	onOpened       []func(OpenedEvent)
+	onClicked      []func(ClickedEvent)
+	onBounce       []func(BounceEvent)
+	onComplained   []func(ComplainedEvent)
+	onUnsubscribed []func(UnsubscribedEvent)
+}
+
+// NewHandler returns a Handler that verifies webhooks using signingKey (the
+// Mailgun HTTP webhook signing key). db is optional; pass nil to skip
+// deduplication, suppression, and persistence.
+func NewHandler(signingKey string, db *sql.DB) (*Handler, error) {
+	if signingKey == "" {
+		return nil, fmt.Errorf("webhook: signing key is required")
+	}
+
+	if db != nil {
+		if err := ensureSchema(db); err != nil {
+			return nil, fmt.Errorf("webhook: failed to prepare schema: %w", err)
+		}
+	}
+
+	return &Handler{signingKey: signingKey, db: db}, nil
+}
+
+func (h *Handler) OnDelivered(fn func(DeliveredEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onDelivered = append(h.onDelivered, fn)
+}
+
+func (h *Handler) OnOpened(fn func(OpenedEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onOpened = append(h.onOpened, fn)
+}
+
+func (h *Handler) OnClicked(fn func(ClickedEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onClicked = append(h.onClicked, fn)
+}
+
+func (h *Handler) OnBounce(fn func(BounceEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onBounce = append(h.onBounce, fn)
+}
+
+func (h *Handler) OnComplained(fn func(ComplainedEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onComplained = append(h.onComplained, fn)
+}
+
+func (h *Handler) OnUnsubscribed(fn func(UnsubscribedEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onUnsubscribed = append(h.onUnsubscribed, fn)
+}
+
+// ServeHTTP implements http.Handler for Mailgun's webhook POST body: a
+// "signature" (timestamp, token, HMAC-SHA256 signature) alongside the raw
+// "event-data".
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Signature struct {
+			Timestamp string `json:"timestamp"`
+			Token     string `json:"token"`
+			Signature string `json:"signature"`
+		} `json:"signature"`
+		EventData json.RawMessage `json:"event-data"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if !verifySignature(h.signingKey, payload.Signature.Timestamp, payload.Signature.Token, payload.Signature.Signature) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := mailgun.ParseEvent(payload.EventData)
+	if err != nil {
+		http.Error(w, "unrecognized event", http.StatusBadRequest)
+		return
+	}
+
+	// Mailgun retries webhooks it didn't get a 200 for, so HandleEvent's
+	// dedupe by signature token keeps a retried delivery from firing
+	// callbacks twice.
+	if err := h.HandleEvent(r.Context(), payload.Signature.Token, event, payload.EventData); err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleEvent records event under dedupeKey and, unless a record already
+// exists for that key, dispatches it via Dispatch. It is the shared path
+// behind both ServeHTTP, which keys by the webhook signature token, and
+// Poller.Run, which keys by the event's Mailgun ID since polled events carry
+// no signature token — so persisted history and deduplication behave the
+// same way regardless of whether an event arrived via webhook push or the
+// polling fallback.
+func (h *Handler) HandleEvent(ctx context.Context, dedupeKey string, event mailgun.Event, payload []byte) error {
+	if h.db != nil {
+		seen, err := recordEvent(ctx, h.db, dedupeKey, event.GetName(), payload)
+		if err != nil {
+			return err
+		}
+		if seen {
+			return nil
+		}
+	}
+
+	return h.Dispatch(ctx, event)
+}
+
+// Dispatch converts a parsed Mailgun event into the matching typed callback
+// and, for hard bounces and complaints, suppresses the recipient. HandleEvent
+// calls it for both webhook and polled events once deduplication has passed;
+// called directly, it neither records nor deduplicates the event. It returns
+// an error only if persisting a
+// suppression fails, so a transient DB failure there isn't silently lost.
+func (h *Handler) Dispatch(ctx context.Context, event mailgun.Event) error {
+	switch e := event.(type) {
+	case *events.Delivered:
+		h.notifyDelivered(DeliveredEvent{
+			Recipient: e.Recipient,
+			MessageID: e.GetID(),
+			Timestamp: e.GetTimestamp(),
+		})
+	case *events.Opened:
+		h.notifyOpened(OpenedEvent{
+			Recipient:  e.Recipient,
+			IP:         e.IP,
+			ClientName: e.ClientInfo.ClientName,
+			Timestamp:  e.GetTimestamp(),
+		})
+	case *events.Clicked:
+		h.notifyClicked(ClickedEvent{
+			Recipient: e.Recipient,
+			URL:       e.Url,
+			Timestamp: e.GetTimestamp(),
+		})
+	case *events.Failed:
+		bounce := BounceEvent{
+			Recipient: e.Recipient,
+			Severity:  e.Severity,
+			Reason:    e.Reason,
+			Timestamp: e.GetTimestamp(),
+		}
+		h.notifyBounce(bounce)
+		if bounce.Severity == "permanent" {
+			if err := suppress(ctx, h.db, bounce.Recipient, "hard bounce"); err != nil {
+				return fmt.Errorf("webhook: failed to suppress hard-bounced recipient %s: %w", bounce.Recipient, err)
+			}
+		}
+	case *events.Complained:
+		h.notifyComplained(ComplainedEvent{Recipient: e.Recipient, Timestamp: e.GetTimestamp()})
+		if err := suppress(ctx, h.db, e.Recipient, "spam complaint"); err != nil {
+			return fmt.Errorf("webhook: failed to suppress complaining recipient %s: %w", e.Recipient, err)
+		}
+	case *events.Unsubscribed:
+		h.notifyUnsubscribed(UnsubscribedEvent{Recipient: e.Recipient, Timestamp: e.GetTimestamp()})
+	}
+
+	return nil
+}
+
+func (h *Handler) notifyDelivered(e DeliveredEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(DeliveredEvent){}, h.onDelivered...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+func (h *Handler) notifyOpened(e OpenedEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(OpenedEvent){}, h.onOpened...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+func (h *Handler) notifyClicked(e ClickedEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(ClickedEvent){}, h.onClicked...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+func (h *Handler) notifyBounce(e BounceEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(BounceEvent){}, h.onBounce...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+func (h *Handler) notifyComplained(e ComplainedEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(ComplainedEvent){}, h.onComplained...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+func (h *Handler) notifyUnsubscribed(e UnsubscribedEvent) {
+	h.mu.Lock()
+	callbacks := append([]func(UnsubscribedEvent){}, h.onUnsubscribed...)
+	h.mu.Unlock()
+	for _, cb := range callbacks {
+		cb(e)
+	}
+}
+
+// verifySignature recomputes the HMAC-SHA256 of timestamp+token using
+// signingKey and compares it against signature in constant time.
+func verifySignature(signingKey, timestamp, token, signature string) bool {
+	if signingKey == "" || timestamp == "" || token == "" || signature == "" {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func sign(signingKey, timestamp, token string) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	mac.Write([]byte(timestamp + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	const signingKey = "test-signing-key"
+	const timestamp = "1234567890"
+	const token = "test-token"
+	validSignature := sign(signingKey, timestamp, token)
+
+	tests := []struct {
+		name       string
+		signingKey string
+		timestamp  string
+		token      string
+		signature  string
+		want       bool
+	}{
+		{"valid", signingKey, timestamp, token, validSignature, true},
+		{"wrong signing key", "wrong-key", timestamp, token, validSignature, false},
+		{"wrong timestamp", signingKey, "0", token, validSignature, false},
+		{"wrong token", signingKey, timestamp, "other-token", validSignature, false},
+		{"tampered signature", signingKey, timestamp, token, validSignature[:len(validSignature)-1] + "0", false},
+		{"missing signing key", "", timestamp, token, validSignature, false},
+		{"missing timestamp", signingKey, "", token, validSignature, false},
+		{"missing token", signingKey, timestamp, "", validSignature, false},
+		{"missing signature", signingKey, timestamp, token, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := verifySignature(tt.signingKey, tt.timestamp, tt.token, tt.signature)
+			if got != tt.want {
+				t.Errorf("verifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mailgun/mailgun-go/v4"
+)
+
+// Poller periodically fetches new events from Mailgun's events API and
+// feeds them through a Handler, for environments where Mailgun can't reach
+// an inbound webhook endpoint.
+type Poller struct {
+	handler *Handler
+	client  mailgun.Mailgun
+	opts    mailgun.ListEventOptions
+}
+
+// NewPoller returns a Poller that dispatches events it fetches from client
+// to handler, using opts to configure the events query (Begin, Limit,
+// PollInterval, and so on).
+func NewPoller(handler *Handler, client mailgun.Mailgun, opts mailgun.ListEventOptions) *Poller {
+	return &Poller{handler: handler, client: client, opts: opts}
+}
+
+// Run polls Mailgun's events API, routing each new event through the
+// handler's HandleEvent (keyed by the event's Mailgun ID, since polled
+// events carry no webhook signature token) so it is persisted and
+// deduplicated the same way a pushed webhook delivery would be, until ctx is
+// cancelled or a request fails.
+func (p *Poller) Run(ctx context.Context) error {
+	it := p.client.PollEvents(&p.opts)
+
+	var batch []mailgun.Event
+	for it.Poll(ctx, &batch) {
+		for _, event := range batch {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("webhook: failed to marshal polled event %s: %w", event.GetID(), err)
+			}
+			if err := p.handler.HandleEvent(ctx, event.GetID(), event, payload); err != nil {
+				return fmt.Errorf("webhook: failed to handle polled event %s: %w", event.GetID(), err)
+			}
+		}
+	}
+
+	return it.Err()
+}
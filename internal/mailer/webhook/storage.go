@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+)
+
+func ensureSchema(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS mailgun_webhook_events (
+			token text PRIMARY KEY,
+			event text NOT NULL,
+			payload jsonb NOT NULL,
+			received_at timestamptz NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS suppressed_recipients (
+			email text PRIMARY KEY,
+			reason text NOT NULL,
+			suppressed_at timestamptz NOT NULL DEFAULT now()
+		)
+	`)
+	return err
+}
+
+// recordEvent persists a dedupe key for an event, reporting whether the key
+// had already been recorded. key is the webhook signature token for events
+// delivered over HTTP, or the event's Mailgun ID for events fetched by
+// Poller, since polled events carry no signature token of their own.
+func recordEvent(ctx context.Context, db *sql.DB, key, event string, payload []byte) (seen bool, err error) {
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO mailgun_webhook_events (token, event, payload) VALUES ($1, $2, $3) ON CONFLICT (token) DO NOTHING`,
+		key, event, payload,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 0, nil
+}
+
+func suppress(ctx context.Context, db *sql.DB, email, reason string) error {
+	if db == nil || email == "" {
+		return nil
+	}
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO suppressed_recipients (email, reason) VALUES ($1, $2)
+		 ON CONFLICT (email) DO UPDATE SET reason = EXCLUDED.reason, suppressed_at = now()`,
+		email, reason,
+	)
+	return err
+}
+
+// IsSuppressed reports whether email has hard-bounced or complained and
+// should be excluded from future sends. It always returns false when the
+// Handler was constructed without a database.
+func (h *Handler) IsSuppressed(ctx context.Context, email string) (bool, error) {
+	if h.db == nil {
+		return false, nil
+	}
+
+	var exists bool
+	err := h.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM suppressed_recipients WHERE email = $1)`, email,
+	).Scan(&exists)
+
+	return exists, err
+}